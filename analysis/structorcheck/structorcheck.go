@@ -0,0 +1,308 @@
+// Package structorcheck implements a go/analysis Analyzer which statically
+// checks struct tags meant to be consumed by structor (see
+// github.com/nikolay-turpitko/structor), turning common tag mistakes into
+// build-time diagnostics instead of runtime *multierror.Error values
+// discovered at Eval time.
+package structorcheck
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer reports mistakes in struct tags consumed by structor: tag
+// expressions that fail to parse, references to `.Struct.X` fields which
+// don't exist on the enclosing struct, and `set` tags that put a non-nil
+// value onto a non-pointer struct field, which structor's reflectSet
+// silently drops instead of assigning or erroring when the value isn't
+// convertible to the field's type.
+var Analyzer = &analysis.Analyzer{
+	Name:     "structorcheck",
+	Doc:      "check struct tags consumed by structor for common mistakes",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// defaultTagNames is used for files which don't declare a
+// `//structor:interpreters` directive.
+var defaultTagNames = []string{"eval"}
+
+// builtinFuncs are the funcs el.DefaultInterpreter's Execute always injects
+// before running a tag's template, regardless of any user-supplied Funcs:
+// "set" is the idiomatic way a non-string field's eval tag assigns its
+// result, and "eval" backs el.Context.EvalExpr. They're registered here as
+// no-op stubs purely so text/template.Parse, which validates function names
+// against the FuncMap at parse time, doesn't reject any real tag using them.
+var builtinFuncs = template.FuncMap{
+	"set":  func(v interface{}) interface{} { return v },
+	"eval": func(args ...interface{}) (interface{}, error) { return nil, nil },
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	tagNames := tagNamesFor(pass)
+	funcs := funcsFor(pass)
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		fieldNames := make(map[string]bool, len(st.Fields.List))
+		for _, f := range st.Fields.List {
+			for _, name := range f.Names {
+				fieldNames[name.Name] = true
+			}
+		}
+		for _, f := range st.Fields.List {
+			if f.Tag == nil || len(f.Names) != 1 {
+				continue
+			}
+			checkField(pass, tagNames, funcs, fieldNames, f)
+		}
+	})
+	return nil, nil
+}
+
+// tagNamesFor reads a file-level `//structor:interpreters name1,name2`
+// directive, falling back to defaultTagNames if none is present.
+func tagNamesFor(pass *analysis.Pass) []string {
+	const prefix = "//structor:interpreters "
+	for _, file := range pass.Files {
+		for _, cg := range file.Comments {
+			for _, c := range cg.List {
+				if strings.HasPrefix(c.Text, prefix) {
+					parts := strings.Split(strings.TrimPrefix(c.Text, prefix), ",")
+					names := make([]string, 0, len(parts))
+					for _, p := range parts {
+						if p = strings.TrimSpace(p); p != "" {
+							names = append(names, p)
+						}
+					}
+					if len(names) > 0 {
+						return names
+					}
+				}
+			}
+		}
+	}
+	return defaultTagNames
+}
+
+// funcsFor returns builtinFuncs overlaid with no-op stubs for the names
+// declared in a file-level `//structor:funcs name1,name2` directive, so
+// expressions calling a project's own custom el.Funcs parse cleanly too.
+func funcsFor(pass *analysis.Pass) template.FuncMap {
+	const prefix = "//structor:funcs "
+	funcs := make(template.FuncMap, len(builtinFuncs))
+	for name, fn := range builtinFuncs {
+		funcs[name] = fn
+	}
+	for _, file := range pass.Files {
+		for _, cg := range file.Comments {
+			for _, c := range cg.List {
+				if strings.HasPrefix(c.Text, prefix) {
+					for _, p := range strings.Split(strings.TrimPrefix(c.Text, prefix), ",") {
+						if p = strings.TrimSpace(p); p != "" {
+							funcs[p] = func(args ...interface{}) (interface{}, error) { return nil, nil }
+						}
+					}
+				}
+			}
+		}
+	}
+	return funcs
+}
+
+func checkField(
+	pass *analysis.Pass,
+	tagNames []string,
+	funcs template.FuncMap,
+	fieldNames map[string]bool,
+	f *ast.Field) {
+	raw, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return
+	}
+	tag := reflect.StructTag(raw)
+	for _, tn := range tagNames {
+		if expr, ok := tag.Lookup(tn); ok {
+			checkExpr(pass, f, tn, expr, funcs, fieldNames)
+		}
+	}
+}
+
+func checkExpr(
+	pass *analysis.Pass,
+	f *ast.Field,
+	tagName, expr string,
+	funcs template.FuncMap,
+	fieldNames map[string]bool) {
+	// el.DefaultInterpreter with AutoEnclose set encloses bare expressions
+	// into "{{ }}"; try that form first, then fall back to treating expr as
+	// an already-delimited template.
+	t, err := template.New(f.Names[0].Name).Funcs(funcs).Parse("{{" + expr + "}}")
+	if err != nil {
+		t, err = template.New(f.Names[0].Name).Funcs(funcs).Parse(expr)
+	}
+	if err != nil {
+		pass.Reportf(f.Tag.Pos(), "structor: %s tag %q failed to parse: %v", tagName, expr, err)
+		return
+	}
+	for _, ref := range collectStructRefs(t.Root) {
+		if !fieldNames[ref] {
+			pass.Reportf(f.Tag.Pos(),
+				"structor: %s tag %q references .Struct.%s, which is not a field of this struct",
+				tagName, expr, ref)
+		}
+	}
+	if hasNonNilSet(t.Root) {
+		if typ := pass.TypesInfo.TypeOf(f.Type); typ != nil && isNonPointerStruct(typ) {
+			pass.Reportf(f.Tag.Pos(),
+				"structor: %s tag %q sets a non-nil value on non-pointer struct field %s; "+
+					"if it isn't convertible to the field's type, structor's reflectSet "+
+					"silently drops it instead of assigning or erroring",
+				tagName, expr, f.Names[0].Name)
+		}
+	}
+}
+
+func isNonPointerStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// hasNonNilSet reports whether n contains a "set" call whose value isn't
+// simply the literal "nil". "{{set nil}}" is safe: reflectSet special-cases
+// nv == nil by zeroing the field with reflect.Zero. Any other value reaching
+// a struct-kind field's reflectSet, if not ConvertibleTo the field's type,
+// is silently dropped instead of assigned or erroring.
+func hasNonNilSet(n parse.Node) bool {
+	found := false
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		if found {
+			return
+		}
+		switch v := n.(type) {
+		case *parse.ListNode:
+			if v == nil {
+				return
+			}
+			for _, c := range v.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(v.Pipe)
+		case *parse.PipeNode:
+			for _, c := range v.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			if id, ok := v.Args[0].(*parse.IdentifierNode); ok && id.Ident == "set" {
+				rest := v.Args[1:]
+				isNil := len(rest) == 1
+				if isNil {
+					_, isNil = rest[0].(*parse.NilNode)
+				}
+				if !isNil {
+					found = true
+					return
+				}
+			}
+			for _, a := range v.Args {
+				walk(a)
+			}
+		case *parse.IfNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		case *parse.RangeNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		case *parse.WithNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		}
+	}
+	walk(n)
+	return found
+}
+
+// collectStructRefs returns the names of struct fields referenced through
+// `.Struct.X` anywhere in the parsed template tree.
+func collectStructRefs(n parse.Node) []string {
+	var refs []string
+	seen := map[string]bool{}
+	record := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			refs = append(refs, name)
+		}
+	}
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch v := n.(type) {
+		case *parse.ListNode:
+			if v == nil {
+				return
+			}
+			for _, c := range v.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(v.Pipe)
+		case *parse.PipeNode:
+			for _, c := range v.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range v.Args {
+				walk(a)
+			}
+		case *parse.FieldNode:
+			if len(v.Ident) >= 2 && v.Ident[0] == "Struct" {
+				record(v.Ident[1])
+			}
+		case *parse.ChainNode:
+			walk(v.Node)
+			if fld, ok := v.Node.(*parse.FieldNode); ok &&
+				len(fld.Ident) == 1 && fld.Ident[0] == "Struct" && len(v.Field) >= 1 {
+				record(v.Field[0])
+			}
+		case *parse.IfNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		case *parse.RangeNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		case *parse.WithNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		}
+	}
+	walk(n)
+	return refs
+}
@@ -0,0 +1,13 @@
+package structorcheck_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/nikolay-turpitko/structor/analysis/structorcheck"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), structorcheck.Analyzer, "a")
+}
@@ -0,0 +1,14 @@
+//structor:funcs atoi
+package a
+
+type Inner struct{}
+
+type Obj struct {
+	A string `eval:"{{.Struct.Missing}}"` // want `references .Struct.Missing, which is not a field of this struct`
+	B string `eval:"{{.Struct.C}}"`
+	C string `eval:"ccc"`
+	D string `eval:"{{.Bad"` // want `failed to parse`
+	E int    `eval:"{{set (atoi .Struct.C)}}"`
+	F Inner  `eval:"{{set nil}}"`
+	G Inner  `eval:"{{set .Struct.C}}"` // want `sets a non-nil value on non-pointer struct field G`
+}
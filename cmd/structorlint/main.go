@@ -0,0 +1,16 @@
+// Command structorlint is a go vet-style static checker for struct tags
+// consumed by structor. Run it the same way as any other go/analysis
+// single-checker, e.g.:
+//
+//	structorlint ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/nikolay-turpitko/structor/analysis/structorcheck"
+)
+
+func main() {
+	singlechecker.Main(structorcheck.Analyzer)
+}
@@ -0,0 +1,124 @@
+// Package config lets structor evaluation targets be driven by an external
+// YAML or JSON document instead of requiring the eval tags to be compiled
+// into the target's Go source.
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/nikolay-turpitko/structor"
+	"github.com/nikolay-turpitko/structor/scanner"
+)
+
+// tagsKey is the reserved top-level document key holding per-field tag
+// overrides. It is never assigned to a target field.
+const tagsKey = "_eval"
+
+// Eval unmarshals doc (YAML or JSON; yaml.v3 parses both) into target, then
+// runs ev.Eval on it. If doc has a top-level "_eval" map of field name to
+// tag name/value, each named field's compile-time tags are overlaid with
+// the ones from the document before evaluation, letting ops-driven
+// deployments change templated fields (shell commands, xpath queries,
+// encryption keys, ...) without recompiling.
+//
+// ev must have been built with structor.NewEvaluator or
+// structor.NewEvaluatorWithOptions (or another Evaluator which supports the
+// same tag-source overlay) when doc carries "_eval" overrides; otherwise
+// Eval returns an error.
+func Eval(doc []byte, target interface{}, ev structor.Evaluator) error {
+	var generic map[string]interface{}
+	if err := yaml.Unmarshal(doc, &generic); err != nil {
+		return fmt.Errorf("structor/config: %v", err)
+	}
+	overrides, err := parseOverrides(generic[tagsKey])
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(doc, target); err != nil {
+		return fmt.Errorf("structor/config: %v", err)
+	}
+	if len(overrides) == 0 {
+		return ev.Eval(target, nil)
+	}
+	withTagSource, ok := ev.(interface {
+		WithTagSource(structor.TagSource) structor.Evaluator
+	})
+	if !ok {
+		return fmt.Errorf(
+			"structor/config: evaluator %T doesn't support tag source overlays, "+
+				"but document has %q overrides", ev, tagsKey)
+	}
+	overlay := &overlaySource{
+		base:      structor.DefaultTagSource(scanner.Default),
+		overrides: overrides,
+	}
+	return withTagSource.WithTagSource(overlay).Eval(target, nil)
+}
+
+func parseOverrides(v interface{}) (map[string]map[string]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(
+			"structor/config: %q must be a map of field name to tag map", tagsKey)
+	}
+	res := make(map[string]map[string]string, len(m))
+	for field, rawTags := range m {
+		tm, ok := rawTags.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"structor/config: %s.%s must be a map of tag name to value", tagsKey, field)
+		}
+		tags := make(map[string]string, len(tm))
+		for k, tv := range tm {
+			tags[k] = fmt.Sprintf("%v", tv)
+		}
+		res[field] = tags
+	}
+	return res, nil
+}
+
+// overlaySource is a structor.TagSource which merges per-field overrides
+// parsed from a config document over a base TagSource's tags, the override
+// winning on key collision.
+type overlaySource struct {
+	base      structor.TagSource
+	overrides map[string]map[string]string
+}
+
+func (o *overlaySource) Tags(t reflect.Type, name string) (map[string]string, error) {
+	base, err := o.base.Tags(t, name)
+	if err != nil {
+		return nil, err
+	}
+	over, ok := o.overrides[name]
+	if !ok {
+		// The document keys "_eval" by the document's own field spelling
+		// (usually the lower-cased yaml key), not necessarily the Go field
+		// name structor calls Tags with, so fall back to a case-insensitive
+		// match before giving up.
+		for k, v := range o.overrides {
+			if strings.EqualFold(k, name) {
+				over, ok = v, true
+				break
+			}
+		}
+		if !ok {
+			return base, nil
+		}
+	}
+	merged := make(map[string]string, len(base)+len(over))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range over {
+		merged[k] = v
+	}
+	return merged, nil
+}
@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nikolay-turpitko/structor"
+	"github.com/nikolay-turpitko/structor/config"
+	"github.com/nikolay-turpitko/structor/el"
+	"github.com/nikolay-turpitko/structor/scanner"
+)
+
+// TestEval tests that document data populates the target's fields and that
+// an "_eval" override replaces a field's compile-time tag.
+func TestEval(t *testing.T) {
+	type target struct {
+		Host string `eval:"{{.Val}}"`
+		Port string `eval:"{{.Val}}"`
+	}
+	doc := []byte(`
+host: localhost
+port: "5432"
+_eval:
+  port:
+    eval: "{{.Struct.Host}}"
+`)
+	ev := structor.NewEvaluator(
+		scanner.Default,
+		structor.Interpreters{"eval": &el.DefaultInterpreter{}})
+	v := &target{}
+	err := config.Eval(doc, v, ev)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v.Host)
+	assert.Equal(t, "localhost", v.Port)
+}
+
+// TestEvalNoOverrides tests that documents without an "_eval" section just
+// populate the target and evaluate it normally.
+func TestEvalNoOverrides(t *testing.T) {
+	type target struct {
+		Host string `eval:"{{.Val}}"`
+	}
+	doc := []byte(`host: localhost`)
+	ev := structor.NewEvaluator(
+		scanner.Default,
+		structor.Interpreters{"eval": &el.DefaultInterpreter{}})
+	v := &target{}
+	err := config.Eval(doc, v, ev)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", v.Host)
+}
@@ -0,0 +1,100 @@
+package structor
+
+import "fmt"
+
+// fieldAnalyzer is implemented by el.Interpreter implementations which can
+// report, for a given expression, the names of sibling struct fields
+// (reachable through `.Struct.X`) it reads. el.DefaultInterpreter implements
+// it by walking the parsed template tree. Interpreters which don't implement
+// it are treated conservatively by buildDepGraph: their field is assumed to
+// depend on every field declared before it, which reproduces the strictly
+// serial, declaration-order evaluation structor had before dependency
+// analysis existed.
+type fieldAnalyzer interface {
+	Analyze(expr string) ([]string, error)
+}
+
+// buildDepGraph returns, for each field index, the indices of the fields it
+// must be evaluated after. names maps a field's Go name to its index.
+//
+// A field whose interpreter implements fieldAnalyzer but fails to analyze
+// its own expression (e.g. because the expression isn't valid on its own,
+// or uses something Analyze can't see through) falls back to the same
+// conservative serial edge used for interpreters which don't implement
+// fieldAnalyzer at all: the real failure, if any, surfaces from Execute
+// during evaluation instead of aborting the whole Eval here.
+func buildDepGraph(names map[string]int, fields []fieldDescr) map[int][]int {
+	deps := make(map[int][]int, len(fields))
+	for i, f := range fields {
+		if f.expr == "" || f.interpreter == nil {
+			continue
+		}
+		an, ok := f.interpreter.(fieldAnalyzer)
+		if !ok {
+			for j := 0; j < i; j++ {
+				deps[i] = append(deps[i], j)
+			}
+			continue
+		}
+		refs, err := an.Analyze(f.expr)
+		if err != nil {
+			for j := 0; j < i; j++ {
+				deps[i] = append(deps[i], j)
+			}
+			continue
+		}
+		for _, r := range refs {
+			if j, ok := names[r]; ok && j != i {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+	return deps
+}
+
+// topoLevels groups the indices 0..n-1 into levels such that every index
+// only depends (per deps) on indices in earlier levels, so indices within a
+// level can be evaluated concurrently. longName is used to describe the
+// fields involved in a cycle, if one is found.
+func topoLevels(n int, deps map[int][]int, longName func(int) string) ([][]int, error) {
+	indegree := make([]int, n)
+	dependents := make(map[int][]int, n)
+	for i, ds := range deps {
+		indegree[i] = len(ds)
+		for _, d := range ds {
+			dependents[d] = append(dependents[d], i)
+		}
+	}
+	done := make([]bool, n)
+	remaining := n
+	var levels [][]int
+	for remaining > 0 {
+		var level []int
+		for i := 0; i < n; i++ {
+			if !done[i] && indegree[i] == 0 {
+				level = append(level, i)
+			}
+		}
+		if len(level) == 0 {
+			var stuck []string
+			for i := 0; i < n; i++ {
+				if !done[i] {
+					stuck = append(stuck, longName(i))
+				}
+			}
+			return nil, fmt.Errorf(
+				"structor: circular field dependency involving: %v", stuck)
+		}
+		for _, i := range level {
+			done[i] = true
+			remaining--
+		}
+		for _, i := range level {
+			for _, dep := range dependents[i] {
+				indegree[dep]--
+			}
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
@@ -0,0 +1,134 @@
+package el
+
+import (
+	"errors"
+	"text/template"
+	"text/template/parse"
+)
+
+// Analyze parses expr the same way Execute does and returns the names of
+// the fields it reads through `.Struct.X`, without actually evaluating
+// anything. It lets structor build a dependency graph across struct fields
+// instead of assuming strictly serial, declaration-order evaluation.
+//
+// Only direct `.Struct.X` references are reported; expressions which may
+// reach a field indirectly (a bare `.Struct` or `.` handed to a helper func,
+// `.Sub`, or `.Extra`) aren't analyzable: Analyze returns an error for them,
+// so callers fall back to their conservative default.
+func (di *DefaultInterpreter) Analyze(expr string) ([]string, error) {
+	l, r := di.delims()
+	t, err := template.New("").Delims(l, r).Funcs(di.analyzeFuncs()).Parse(di.enclose(expr))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := map[string]bool{}
+	opaque := false
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch v := n.(type) {
+		case *parse.ActionNode:
+			walk(v.Pipe)
+		case *parse.PipeNode:
+			for _, c := range v.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range v.Args {
+				walk(a)
+			}
+		case *parse.FieldNode:
+			if len(v.Ident) >= 2 && v.Ident[0] == "Struct" {
+				if name := v.Ident[1]; !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			} else if v.Ident[0] == "Struct" || v.Ident[0] == "Sub" || v.Ident[0] == "Extra" {
+				opaque = true
+			}
+		case *parse.DotNode:
+			opaque = true
+		case *parse.ChainNode:
+			walk(v.Node)
+			if f, ok := v.Node.(*parse.FieldNode); ok &&
+				len(f.Ident) == 1 && f.Ident[0] == "Struct" &&
+				len(v.Field) >= 1 {
+				if name := v.Field[0]; !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		case *parse.IfNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		case *parse.RangeNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		case *parse.WithNode:
+			walk(v.Pipe)
+			walk(v.List)
+			if v.ElseList != nil {
+				walk(v.ElseList)
+			}
+		case *parse.ListNode:
+			if v == nil {
+				return
+			}
+			for _, c := range v.Nodes {
+				walk(c)
+			}
+		}
+	}
+	walk(t.Root)
+	if opaque {
+		return nil, errors.New("el: expression may read struct fields indirectly")
+	}
+	return names, nil
+}
+
+// enclose returns expr wrapped in the interpreter's delimiters when
+// AutoEnclose is set, mirroring what Execute does before handing expr to
+// text/template.
+func (di *DefaultInterpreter) enclose(expr string) string {
+	if !di.AutoEnclose {
+		return expr
+	}
+	l, r := di.delims()
+	return l + expr + r
+}
+
+// delims returns di.LeftDelim/di.RightDelim, falling back to text/template's
+// own defaults when either is unset, same as Execute does.
+func (di *DefaultInterpreter) delims() (string, string) {
+	l, r := di.LeftDelim, di.RightDelim
+	if l == "" {
+		l = "{{"
+	}
+	if r == "" {
+		r = "}}"
+	}
+	return l, r
+}
+
+// analyzeFuncs returns no-op stubs for the "set" and "eval" funcs Execute
+// always injects, overlaid with di.Funcs (the former is the idiomatic way
+// every non-string field's eval tag assigns its result; the latter backs
+// el.Context.EvalExpr). Without them, Parse fails on any such tag with
+// "function not defined", since text/template validates function names
+// against the FuncMap it was given at parse time.
+func (di *DefaultInterpreter) analyzeFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		"set":  func(v interface{}) interface{} { return v },
+		"eval": func(args ...interface{}) (interface{}, error) { return nil, nil },
+	}
+	for name, fn := range di.Funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
@@ -0,0 +1,411 @@
+package el
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UnifyInterpreter is an el.Interpreter registered under a tag like `cue:`
+// (or `unify:`) which treats the tag body as a small CUE-like constraint
+// expression and unifies it with the field's current value
+// (el.Context.Val) - typically already written by an earlier `eval:` tag on
+// the same field.
+//
+// Supported expressions, composed with `&` (unify) and `|` (disjunction):
+//
+//	int & >=0 & <=100            numeric range
+//	string & =~"^[a-z]+$"        regexp match
+//	*"prod" | "dev" | "staging"  disjunction with a default, marked with `*`
+//	[]int                        slice of a constrained element type
+//
+// If the current value satisfies the expression it's returned unchanged.
+// If the expression is a disjunction with a `*`-marked default and the
+// current value is the zero value, the default is returned. Otherwise
+// Execute returns an error describing which part of the expression failed.
+type UnifyInterpreter struct{}
+
+// Execute implements el.Interpreter.
+func (UnifyInterpreter) Execute(expr string, ctx *Context) (interface{}, error) {
+	n, err := parseUnify(expr)
+	if err != nil {
+		return nil, fmt.Errorf("unify: %v", err)
+	}
+	v, err := n.unify(ctx.Val)
+	if err != nil {
+		return nil, fmt.Errorf("unify: %v", err)
+	}
+	return v, nil
+}
+
+// unifyNode is a node of a parsed constraint expression.
+type unifyNode interface {
+	unify(val interface{}) (interface{}, error)
+}
+
+type typeNode struct{ name string }
+
+func (n typeNode) unify(val interface{}) (interface{}, error) {
+	if val == nil {
+		return nil, fmt.Errorf("value is nil, want %s", n.name)
+	}
+	switch k := reflect.ValueOf(val).Kind(); n.name {
+	case "int":
+		if !isIntKind(k) {
+			return nil, fmt.Errorf("value %v is not an int", val)
+		}
+	case "float":
+		if !isIntKind(k) && !isFloatKind(k) {
+			return nil, fmt.Errorf("value %v is not a float", val)
+		}
+	case "string":
+		if k != reflect.String {
+			return nil, fmt.Errorf("value %v is not a string", val)
+		}
+	case "bool":
+		if k != reflect.Bool {
+			return nil, fmt.Errorf("value %v is not a bool", val)
+		}
+	default:
+		return nil, fmt.Errorf("unknown type %q", n.name)
+	}
+	return val, nil
+}
+
+type compareNode struct {
+	op  string
+	num float64
+	re  *regexp.Regexp
+}
+
+func (n compareNode) unify(val interface{}) (interface{}, error) {
+	if n.op == "=~" {
+		s := fmt.Sprintf("%v", val)
+		if !n.re.MatchString(s) {
+			return nil, fmt.Errorf("value %q does not match %q", s, n.re.String())
+		}
+		return val, nil
+	}
+	f, err := toFloat(val)
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	switch n.op {
+	case ">=":
+		ok = f >= n.num
+	case "<=":
+		ok = f <= n.num
+	case ">":
+		ok = f > n.num
+	case "<":
+		ok = f < n.num
+	case "!=":
+		ok = f != n.num
+	}
+	if !ok {
+		return nil, fmt.Errorf("value %v fails constraint %s%v", val, n.op, n.num)
+	}
+	return val, nil
+}
+
+type literalNode struct {
+	val    interface{}
+	isDflt bool
+}
+
+func (n literalNode) unify(val interface{}) (interface{}, error) {
+	if fmt.Sprintf("%v", val) == fmt.Sprintf("%v", n.val) {
+		return val, nil
+	}
+	return nil, fmt.Errorf("value %v does not equal %v", val, n.val)
+}
+
+type andNode struct{ left, right unifyNode }
+
+func (n andNode) unify(val interface{}) (interface{}, error) {
+	if _, err := n.left.unify(val); err != nil {
+		return nil, err
+	}
+	return n.right.unify(val)
+}
+
+type disjNode struct {
+	options []unifyNode
+	dfltIdx int // -1 if no option was marked with `*`
+}
+
+func (n disjNode) unify(val interface{}) (interface{}, error) {
+	if n.dfltIdx >= 0 && isZeroVal(val) {
+		return n.options[n.dfltIdx].(literalNode).val, nil
+	}
+	var errs []string
+	for _, o := range n.options {
+		if v, err := o.unify(val); err == nil {
+			return v, nil
+		} else {
+			errs = append(errs, err.Error())
+		}
+	}
+	return nil, fmt.Errorf("value %v satisfies none of: %s", val, strings.Join(errs, "; "))
+}
+
+type sliceNode struct{ elem unifyNode }
+
+func (n sliceNode) unify(val interface{}) (interface{}, error) {
+	if val == nil || reflect.ValueOf(val).Kind() != reflect.Slice {
+		return nil, fmt.Errorf("value %v is not a slice", val)
+	}
+	v := reflect.ValueOf(val)
+	for i := 0; i < v.Len(); i++ {
+		if _, err := n.elem.unify(v.Index(i).Interface()); err != nil {
+			return nil, fmt.Errorf("element %d: %v", i, err)
+		}
+	}
+	return val, nil
+}
+
+func isZeroVal(val interface{}) bool {
+	return val == nil || reflect.ValueOf(val).IsZero()
+}
+
+func toFloat(val interface{}) (float64, error) {
+	switch v := reflect.ValueOf(val); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	default:
+		return 0, fmt.Errorf("value %v is not numeric", val)
+	}
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+// token and the lexer/parser below implement a tiny Pratt-style parser for
+// the constraint grammar: `|` binds loosest, `&` next, then unary prefix
+// operators (`>=`, `=~`, ...) and primaries (type names, literals, `[]T`).
+
+type token struct {
+	kind string // "str", "ident" (also numbers), "op", "star", "lbrack", "rbrack", "eof"
+	text string
+}
+
+func lexUnify(s string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '*':
+			toks = append(toks, token{"star", "*"})
+			i++
+		case c == '[':
+			toks = append(toks, token{"lbrack", "["})
+			i++
+		case c == ']':
+			toks = append(toks, token{"rbrack", "]"})
+			i++
+		case c == '&':
+			toks = append(toks, token{"op", "&"})
+			i++
+		case c == '|':
+			toks = append(toks, token{"op", "|"})
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			str, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{"str", str})
+			i = j + 1
+		case strings.HasPrefix(s[i:], ">=") || strings.HasPrefix(s[i:], "<=") ||
+			strings.HasPrefix(s[i:], "!=") || strings.HasPrefix(s[i:], "=~"):
+			toks = append(toks, token{"op", s[i : i+2]})
+			i += 2
+		case c == '>' || c == '<':
+			toks = append(toks, token{"op", string(c)})
+			i++
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t&|*[]", rune(s[j])) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q", string(c))
+			}
+			toks = append(toks, token{"ident", s[i:j]})
+			i = j
+		}
+	}
+	return append(toks, token{"eof", ""}), nil
+}
+
+type unifyParser struct {
+	toks []token
+	pos  int
+}
+
+func parseUnify(expr string) (unifyNode, error) {
+	toks, err := lexUnify(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, err
+	}
+	p := &unifyParser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != "eof" {
+		return nil, fmt.Errorf("unexpected token %q", p.peek().text)
+	}
+	return n, nil
+}
+
+func (p *unifyParser) peek() token { return p.toks[p.pos] }
+
+func (p *unifyParser) next() token {
+	t := p.toks[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *unifyParser) parseOr() (unifyNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	opts := []unifyNode{first}
+	for p.peek().kind == "op" && p.peek().text == "|" {
+		p.next()
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, n)
+	}
+	if len(opts) == 1 {
+		return opts[0], nil
+	}
+	dflt := -1
+	for i, o := range opts {
+		if lit, ok := o.(literalNode); ok && lit.isDflt {
+			dflt = i
+			break
+		}
+	}
+	return disjNode{options: opts, dfltIdx: dflt}, nil
+}
+
+func (p *unifyParser) parseAnd() (unifyNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == "op" && p.peek().text == "&" {
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *unifyParser) parsePrimary() (unifyNode, error) {
+	t := p.peek()
+	switch t.kind {
+	case "star":
+		p.next()
+		n, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		lit, ok := n.(literalNode)
+		if !ok {
+			return nil, fmt.Errorf("'*' default marker must precede a literal")
+		}
+		lit.isDflt = true
+		return lit, nil
+	case "lbrack":
+		p.next()
+		if p.peek().kind != "rbrack" {
+			return nil, fmt.Errorf("expected ']' after '['")
+		}
+		p.next()
+		elem, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return sliceNode{elem}, nil
+	case "op":
+		op := t.text
+		p.next()
+		if op == "=~" {
+			v := p.next()
+			if v.kind != "str" {
+				return nil, fmt.Errorf("=~ expects a string literal")
+			}
+			re, err := regexp.Compile(v.text)
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{op: op, re: re}, nil
+		}
+		v := p.next()
+		n, err := strconv.ParseFloat(v.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a number: %v", op, err)
+		}
+		return compareNode{op: op, num: n}, nil
+	case "ident":
+		p.next()
+		switch t.text {
+		case "int", "float", "string", "bool":
+			return typeNode{t.text}, nil
+		case "true":
+			return literalNode{val: true}, nil
+		case "false":
+			return literalNode{val: false}, nil
+		default:
+			if n, err := strconv.ParseFloat(t.text, 64); err == nil {
+				return literalNode{val: n}, nil
+			}
+			return literalNode{val: t.text}, nil
+		}
+	case "str":
+		p.next()
+		return literalNode{val: t.text}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %q", t.text)
+	}
+}
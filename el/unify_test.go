@@ -0,0 +1,44 @@
+package el
+
+import "testing"
+
+func TestUnifyInterpreterRange(t *testing.T) {
+	in := UnifyInterpreter{}
+	if _, err := in.Execute("int & >=0 & <=100", &Context{Val: 42}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := in.Execute("int & >=0 & <=100", &Context{Val: 101}); err == nil {
+		t.Fatal("expected error for out-of-range value")
+	}
+}
+
+func TestUnifyInterpreterRegexp(t *testing.T) {
+	in := UnifyInterpreter{}
+	if _, err := in.Execute(`string & =~"^[a-z]+$"`, &Context{Val: "abc"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := in.Execute(`string & =~"^[a-z]+$"`, &Context{Val: "ABC"}); err == nil {
+		t.Fatal("expected error for non-matching value")
+	}
+}
+
+func TestUnifyInterpreterDisjunctionDefault(t *testing.T) {
+	in := UnifyInterpreter{}
+	v, err := in.Execute(`*"prod" | "dev" | "staging"`, &Context{Val: ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "prod" {
+		t.Fatalf("expected default %q, got %q", "prod", v)
+	}
+	if _, err := in.Execute(`*"prod" | "dev" | "staging"`, &Context{Val: "qa"}); err == nil {
+		t.Fatal("expected error for value outside the disjunction")
+	}
+	v, err = in.Execute(`*"prod" | "dev" | "staging"`, &Context{Val: "dev"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "dev" {
+		t.Fatalf("expected %q unchanged, got %q", "dev", v)
+	}
+}
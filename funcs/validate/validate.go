@@ -0,0 +1,178 @@
+// Package validate implements an el.Interpreter intended to be registered
+// under a tag like `validate:"required,min=1,email"`. Unlike the "eval"
+// interpreter, it doesn't compute a new field value: it checks
+// el.Context.Val (the field's current, post-eval value) against a
+// comma-separated list of rules and reports violations, leaving the value
+// itself untouched.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+
+	"github.com/nikolay-turpitko/structor/el"
+)
+
+// Func is a user-registered validator, looked up by name for the
+// `custom=name` rule.
+type Func func(val interface{}) error
+
+// FuncMap is a registry of Func, keyed by the name used in `custom=name`.
+type FuncMap map[string]Func
+
+// Interpreter is an el.Interpreter which validates el.Context.Val against
+// the rules in expr and returns Val unchanged. A non-nil error is a
+// *multierror.Error aggregating every violated rule.
+//
+//	required        value must not be the zero value
+//	min=N, max=N     numeric bound, or length bound for string/slice/map
+//	oneof=a b c      value (formatted with %v) must equal one of the options
+//	regexp=pattern   value (formatted with %v) must match pattern
+//	email            value must be a valid email address
+//	url              value must be a valid URL
+//	custom=name      delegate to Funcs[name]
+type Interpreter struct {
+	Funcs FuncMap
+}
+
+// Execute implements el.Interpreter.
+func (in *Interpreter) Execute(expr string, ctx *el.Context) (interface{}, error) {
+	rules, err := parseRules(expr)
+	if err != nil {
+		return ctx.Val, err
+	}
+	var merr error
+	for _, r := range rules {
+		if err := r.check(ctx.Val, in.Funcs); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	return ctx.Val, merr
+}
+
+type rule struct {
+	name string
+	arg  string
+}
+
+func parseRules(expr string) ([]rule, error) {
+	var rules []rule
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		rules = append(rules, rule{strings.TrimSpace(name), strings.TrimSpace(arg)})
+	}
+	return rules, nil
+}
+
+func (r rule) check(val interface{}, funcs FuncMap) error {
+	switch r.name {
+	case "required":
+		if isZero(val) {
+			return fmt.Errorf("validate: value is required")
+		}
+		return nil
+	case "min":
+		return checkBound(val, r.arg, true)
+	case "max":
+		return checkBound(val, r.arg, false)
+	case "oneof":
+		return checkOneOf(val, r.arg)
+	case "regexp":
+		return checkRegexp(val, r.arg)
+	case "email":
+		return checkEmail(val)
+	case "url":
+		return checkURL(val)
+	case "custom":
+		fn, ok := funcs[r.arg]
+		if !ok {
+			return fmt.Errorf("validate: unknown custom validator: %s", r.arg)
+		}
+		return fn(val)
+	default:
+		return fmt.Errorf("validate: unknown rule: %s", r.name)
+	}
+}
+
+func isZero(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	return reflect.ValueOf(val).IsZero()
+}
+
+func checkBound(val interface{}, arg string, isMin bool) error {
+	n, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return fmt.Errorf("validate: invalid bound %q: %v", arg, err)
+	}
+	v := reflect.ValueOf(val)
+	var actual float64
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		actual = float64(v.Len())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = v.Float()
+	default:
+		return fmt.Errorf("validate: min/max not supported for kind %v", v.Kind())
+	}
+	if isMin && actual < n {
+		return fmt.Errorf("validate: value %v is less than min %v", val, n)
+	}
+	if !isMin && actual > n {
+		return fmt.Errorf("validate: value %v is greater than max %v", val, n)
+	}
+	return nil
+}
+
+func checkOneOf(val interface{}, arg string) error {
+	s := fmt.Sprintf("%v", val)
+	opts := strings.Fields(arg)
+	for _, opt := range opts {
+		if s == opt {
+			return nil
+		}
+	}
+	return fmt.Errorf("validate: value %q is not one of %v", s, opts)
+}
+
+func checkRegexp(val interface{}, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("validate: invalid regexp %q: %v", pattern, err)
+	}
+	if !re.MatchString(fmt.Sprintf("%v", val)) {
+		return fmt.Errorf("validate: value %q does not match %q", val, pattern)
+	}
+	return nil
+}
+
+func checkEmail(val interface{}) error {
+	if _, err := mail.ParseAddress(fmt.Sprintf("%v", val)); err != nil {
+		return fmt.Errorf("validate: %q is not a valid email: %v", val, err)
+	}
+	return nil
+}
+
+func checkURL(val interface{}) error {
+	u, err := url.ParseRequestURI(fmt.Sprintf("%v", val))
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf("validate: %q is not a valid url", val)
+	}
+	return nil
+}
@@ -18,8 +18,10 @@ package structor
 import (
 	"fmt"
 	"reflect"
+	"sync"
 
 	multierror "github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/nikolay-turpitko/structor/el"
 	"github.com/nikolay-turpitko/structor/funcs/use"
@@ -33,6 +35,33 @@ type Evaluator interface {
 	Eval(s, extra interface{}) error
 }
 
+// TagSource supplies the tags to use when evaluating a struct field,
+// decoupling field introspection from reflect.StructTag so tags can be
+// sourced elsewhere, e.g. overlaid from an external document (see the
+// structor/config package).
+type TagSource interface {
+	Tags(structType reflect.Type, fieldName string) (map[string]string, error)
+}
+
+type tagSourceFunc func(reflect.Type, string) (map[string]string, error)
+
+func (f tagSourceFunc) Tags(t reflect.Type, name string) (map[string]string, error) {
+	return f(t, name)
+}
+
+// DefaultTagSource returns the TagSource equivalent to what evaluator uses
+// when Options.TagSource is unset: it looks the field up by name and scans
+// its reflect.StructTag with sc.
+func DefaultTagSource(sc scanner.Scanner) TagSource {
+	return tagSourceFunc(func(t reflect.Type, name string) (map[string]string, error) {
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("structor: unknown field: %s", name)
+		}
+		return sc.Tags(f.Tag)
+	})
+}
+
 // Interpreters is a map of tag names to el.Interpreters.  Used to register
 // different interpreters for different tag names.
 //
@@ -57,8 +86,8 @@ const WholeTag = ""
 // expression per struct field). Different fields of the same struct can be
 // processed using different EL interpreters.
 //
-//  scanner - is a scanner implementation to be used to scan tags.
-//  interpreters - is a map of registered tag names to EL interpreters.
+//	scanner - is a scanner implementation to be used to scan tags.
+//	interpreters - is a map of registered tag names to EL interpreters.
 func NewEvaluator(
 	scanner scanner.Scanner,
 	interpreters Interpreters) Evaluator {
@@ -79,7 +108,7 @@ func NewEvaluatorWithOptions(
 // implementation uses tag "eval" for expressions and EL interpreter, based on
 // `"text/template"`.
 //
-//  funcs - custom functions, available for interpreter;
+//	funcs - custom functions, available for interpreter;
 func NewDefaultEvaluator(funcs use.FuncMap) Evaluator {
 	return NewEvaluator(
 		scanner.Default,
@@ -108,14 +137,57 @@ type evaluator struct {
 }
 
 type Options struct {
-	NonMutating   bool
-	EvalEmptyTags bool
+	NonMutating               bool
+	EvalEmptyTags             bool
+	MaxParallel               int
+	DisableDependencyAnalysis bool
+	Validators                Interpreters
+	TagSource                 TagSource
+	Unify                     Interpreters
+}
+
+// Validator is an interface of a post-evaluation validator, which gets
+// structure and extra context as input, iterates over `s`'s fields and
+// checks a validation tag (if any) against the field's current value.
+type Validator interface {
+	Validate(s, extra interface{}) error
+}
+
+// NewValidator returns a Validator which checks, for every field, the tag
+// matching one of validators against that field's current value.
+//
+// It's implemented on top of the same field-iteration machinery as
+// Evaluator, so it shares Evaluator's aggregation of per-field errors into a
+// single *multierror.Error; unlike Evaluator it never registers an "eval"
+// interpreter, so it can be run as a separate pass after Eval without the
+// two competing over the same struct tag.
+//
+//	scanner - is a scanner implementation to be used to scan tags.
+//	validators - is a map of registered tag names to validation interpreters.
+func NewValidator(scanner scanner.Scanner, validators Interpreters) Validator {
+	if len(validators) == 0 {
+		panic("no validators registered")
+	}
+	return validatingEvaluator{evaluator{scanner, Interpreters{}, Options{Validators: validators}}}
+}
+
+type validatingEvaluator struct{ ev evaluator }
+
+func (v validatingEvaluator) Validate(s, extra interface{}) error {
+	return v.ev.Eval(s, extra)
 }
 
 func (ev evaluator) Eval(s, extra interface{}) error {
 	return ev.eval(s, extra, nil, nil)
 }
 
+// WithTagSource returns a copy of ev which reads field tags from ts instead
+// of scanning them with ev's own scanner.
+func (ev evaluator) WithTagSource(ts TagSource) Evaluator {
+	ev.options.TagSource = ts
+	return ev
+}
+
 func (ev evaluator) evalExpr(
 	intrprName, expr string,
 	ctx *el.Context) (interface{}, error) {
@@ -135,58 +207,163 @@ func (ev evaluator) eval(s, extra, substruct, subctx interface{}) error {
 	if err != nil {
 		return err
 	}
+	n := typ.NumField()
+	fields := make([]fieldDescr, n)
+	names := make(map[string]int, n)
 	var merr error
-	for i, l := 0, typ.NumField(); i < l; i++ {
-		err := func() error {
-			f, err := ev.fieldIntrospect(val, typ, i)
-			longName := fmt.Sprintf("%T.%s", curr, f.name)
-			if err != nil {
-				return fmt.Errorf("structor: <<%s>>: %v", longName, err)
-			}
-			var result interface{}
-			if f.expr != "" || ev.options.EvalEmptyTags {
-				ctx := &el.Context{
-					Name:     f.name,
-					LongName: longName,
-					Tags:     f.tags,
-					Struct:   s,
-					Extra:    extra,
-					Sub:      subctx,
-					EvalExpr: ev.evalExpr,
-				}
-				if f.value.IsValid() {
-					ctx.Val = f.value.Interface()
-				}
-				result, err = f.interpreter.Execute(f.expr, ctx)
-				if err != nil {
-					return err
-				}
-				if !ev.options.NonMutating && f.settable {
-					err := reflectSet(f.value, f.typ, result)
-					if err != nil {
-						return fmt.Errorf("structor: <<%s>>: %v", longName, err)
-					}
+	for i := 0; i < n; i++ {
+		f, err := ev.fieldIntrospect(val, typ, i)
+		if err != nil {
+			merr = multierror.Append(merr, fmt.Errorf(
+				"structor: <<%T.%s>>: %v", curr, f.name, err))
+			f.expr = ""
+			f.interpreter = nil
+		}
+		fields[i] = f
+		names[f.name] = i
+	}
+	longName := func(i int) string {
+		return fmt.Sprintf("%T.%s", curr, fields[i].name)
+	}
+	var levels [][]int
+	if ev.options.DisableDependencyAnalysis {
+		levels = make([][]int, n)
+		for i := 0; i < n; i++ {
+			levels[i] = []int{i}
+		}
+	} else {
+		deps := buildDepGraph(names, fields)
+		var err error
+		levels, err = topoLevels(n, deps, longName)
+		if err != nil {
+			return multierror.Append(merr, err)
+		}
+	}
+	var mu sync.Mutex
+	for _, level := range levels {
+		g := new(errgroup.Group)
+		sem := make(chan struct{}, ev.maxParallel(len(level)))
+		for _, i := range level {
+			i := i
+			sem <- struct{}{}
+			g.Go(func() error {
+				defer func() { <-sem }()
+				if err := ev.evalField(
+					s, extra, curr, subctx, fields[i], longName(i)); err != nil {
+					mu.Lock()
+					merr = multierror.Append(merr, err)
+					mu.Unlock()
 				}
+				return nil
+			})
+		}
+		// Errors are collected via merr above and every goroutine returns
+		// nil, so Wait only blocks until the whole level has been evaluated.
+		if err := g.Wait(); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	return merr
+}
+
+// evalField evaluates a single field's expression (if any) and recurses into
+// it when it turns out to be (or produce) a nested struct, same as the
+// pre-DAG linear pass did.
+func (ev evaluator) evalField(
+	s, extra, curr, subctx interface{},
+	f fieldDescr,
+	longName string) error {
+	var result interface{}
+	if f.expr != "" || ev.options.EvalEmptyTags {
+		ctx := &el.Context{
+			Name:     f.name,
+			LongName: longName,
+			Tags:     f.tags,
+			Struct:   s,
+			Extra:    extra,
+			Sub:      subctx,
+			EvalExpr: ev.evalExpr,
+		}
+		if f.value.IsValid() {
+			ctx.Val = f.value.Interface()
+		}
+		var err error
+		result, err = f.interpreter.Execute(f.expr, ctx)
+		if err != nil {
+			return err
+		}
+		if !ev.options.NonMutating && f.settable {
+			if err := reflectSet(f.value, f.typ, result); err != nil {
+				return fmt.Errorf("structor: <<%s>>: %v", longName, err)
 			}
-			v := f.value
-			k := v.Kind()
-			if k == reflect.Interface {
-				v = v.Elem()
-				k = reflect.Indirect(v).Kind()
-			}
-			if k == reflect.Struct {
-				// process embedded struct with tag
-				return ev.eval(s, extra, byRef(v), result)
-			}
-			return nil
-		}()
+		}
+	}
+	var merr error
+	if f.validateExpr != "" {
+		vctx := &el.Context{
+			Name:     f.name,
+			LongName: longName,
+			Tags:     f.tags,
+			Struct:   s,
+			Extra:    extra,
+			Sub:      subctx,
+			EvalExpr: ev.evalExpr,
+		}
+		if f.value.IsValid() {
+			vctx.Val = f.value.Interface()
+		}
+		if _, err := f.validator.Execute(f.validateExpr, vctx); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("structor: <<%s>>: %v", longName, err))
+		}
+	}
+	if f.unifyExpr != "" {
+		uctx := &el.Context{
+			Name:     f.name,
+			LongName: longName,
+			Tags:     f.tags,
+			Struct:   s,
+			Extra:    extra,
+			Sub:      subctx,
+			EvalExpr: ev.evalExpr,
+		}
+		if f.value.IsValid() {
+			uctx.Val = f.value.Interface()
+		}
+		unified, err := f.unifier.Execute(f.unifyExpr, uctx)
 		if err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("structor: <<%s>>: %v", longName, err))
+		} else if !ev.options.NonMutating && f.settable {
+			if err := reflectSet(f.value, f.typ, unified); err != nil {
+				merr = multierror.Append(merr, fmt.Errorf("structor: <<%s>>: %v", longName, err))
+			}
+		}
+	}
+	v := f.value
+	k := v.Kind()
+	if k == reflect.Interface {
+		v = v.Elem()
+		k = reflect.Indirect(v).Kind()
+	}
+	if k == reflect.Struct {
+		// process embedded struct with tag
+		if err := ev.eval(s, extra, byRef(v), result); err != nil {
 			merr = multierror.Append(merr, err)
 		}
 	}
 	return merr
 }
 
+// maxParallel returns the worker pool size to use for a single dependency
+// level of levelLen mutually-independent fields. A non-positive
+// Options.MaxParallel means "no explicit bound", i.e. evaluate the whole
+// level at once.
+func (ev evaluator) maxParallel(levelLen int) int {
+	if ev.options.MaxParallel > 0 && ev.options.MaxParallel < levelLen {
+		return ev.options.MaxParallel
+	}
+	return levelLen
+}
+
 func (ev evaluator) structIntrospect(
 	s interface{}) (reflect.Value, reflect.Type, error) {
 	v := reflect.Indirect(reflect.ValueOf(s))
@@ -202,13 +379,17 @@ func (ev evaluator) structIntrospect(
 }
 
 type fieldDescr struct {
-	name        string
-	expr        string
-	interpreter el.Interpreter
-	value       reflect.Value
-	typ         reflect.Type
-	tags        map[string]string
-	settable    bool
+	name         string
+	expr         string
+	interpreter  el.Interpreter
+	validateExpr string
+	validator    el.Interpreter
+	unifyExpr    string
+	unifier      el.Interpreter
+	value        reflect.Value
+	typ          reflect.Type
+	tags         map[string]string
+	settable     bool
 }
 
 func (ev evaluator) fieldIntrospect(
@@ -217,7 +398,13 @@ func (ev evaluator) fieldIntrospect(
 	i int) (fieldDescr, error) {
 	f := typ.Field(i)
 	v := reflect.Indirect(val.Field(i))
-	tags, err := ev.scanner.Tags(f.Tag)
+	var tags map[string]string
+	var err error
+	if ev.options.TagSource != nil {
+		tags, err = ev.options.TagSource.Tags(typ, f.Name)
+	} else {
+		tags, err = ev.scanner.Tags(f.Tag)
+	}
 	res := fieldDescr{
 		name:  f.Name,
 		value: v,
@@ -233,14 +420,31 @@ func (ev evaluator) fieldIntrospect(
 			delete(tags, k)
 			res.expr = t
 			res.interpreter = intr
-			return res, nil
+			break
+		}
+	}
+	if res.interpreter == nil {
+		if intr, ok := ev.interpreters[WholeTag]; ok {
+			delete(tags, WholeTag)
+			res.expr = string(f.Tag)
+			res.interpreter = intr
 		}
 	}
-	if intr, ok := ev.interpreters[WholeTag]; ok {
-		delete(tags, WholeTag)
-		res.expr = string(f.Tag)
-		res.interpreter = intr
-		return res, nil
+	for k, t := range tags {
+		if intr, ok := ev.options.Validators[k]; ok {
+			delete(tags, k)
+			res.validateExpr = t
+			res.validator = intr
+			break
+		}
+	}
+	for k, t := range tags {
+		if intr, ok := ev.options.Unify[k]; ok {
+			delete(tags, k)
+			res.unifyExpr = t
+			res.unifier = intr
+			break
+		}
 	}
 	return res, nil
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/nikolay-turpitko/structor/funcs/math"
 	funcs_strings "github.com/nikolay-turpitko/structor/funcs/strings"
 	"github.com/nikolay-turpitko/structor/funcs/use"
+	"github.com/nikolay-turpitko/structor/funcs/validate"
 	"github.com/nikolay-turpitko/structor/scanner"
 )
 
@@ -112,6 +113,80 @@ func TestObj(t *testing.T) {
 	assert.Equal(t, "first", v.P)
 }
 
+// TestDependencyOrder tests that fields referencing `.Struct.X` via a plain
+// `.Struct.X` chain are evaluated in dependency order rather than
+// declaration order, and that independent fields still produce correct
+// results when evaluated concurrently.
+func TestDependencyOrder(t *testing.T) {
+	type obj struct {
+		D string `eval:"{{.Struct.C}} world"`
+		C string `eval:"hello"`
+		E string `eval:"eee"`
+	}
+	v := &obj{}
+	ev := structor.NewDefaultEvaluator(nil)
+	err := ev.Eval(v, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", v.C)
+	assert.Equal(t, "hello world", v.D)
+	assert.Equal(t, "eee", v.E)
+}
+
+// TestCircularDependency tests that a cycle between fields is reported as
+// an error instead of silently using a stale value.
+func TestCircularDependency(t *testing.T) {
+	type obj struct {
+		A string `eval:"{{.Struct.B}}"`
+		B string `eval:"{{.Struct.A}}"`
+	}
+	v := &obj{}
+	ev := structor.NewDefaultEvaluator(nil)
+	err := ev.Eval(v, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "circular field dependency")
+}
+
+// TestValidator tests the post-evaluation validation pass.
+func TestValidator(t *testing.T) {
+	type obj struct {
+		Name string `validate:"required,min=3"`
+		Role string `validate:"oneof=admin user"`
+	}
+	v := &obj{Name: "ab", Role: "guest"}
+	val := structor.NewValidator(
+		scanner.Default,
+		structor.Interpreters{"validate": &validate.Interpreter{}})
+	err := val.Validate(v, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "<<*structor_test.obj.Name>>")
+	assert.Contains(t, err.Error(), "<<*structor_test.obj.Role>>")
+
+	v2 := &obj{Name: "abc", Role: "admin"}
+	assert.NoError(t, val.Validate(v2, nil))
+}
+
+// TestUnify tests a field computed by "eval" and then constrained by the
+// CUE-style "cue" unification interpreter.
+func TestUnify(t *testing.T) {
+	type obj struct {
+		Percent int    `eval:"{{set 42}}" cue:"int & >=0 & <=100"`
+		Env     string `cue:"*\"prod\" | \"dev\" | \"staging\""`
+	}
+	v := &obj{}
+	ev := structor.NewEvaluatorWithOptions(
+		scanner.Default,
+		structor.Interpreters{
+			"eval": &el.DefaultInterpreter{},
+		},
+		structor.Options{
+			Unify: structor.Interpreters{"cue": &el.UnifyInterpreter{}},
+		})
+	err := ev.Eval(v, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v.Percent)
+	assert.Equal(t, "prod", v.Env)
+}
+
 // TestError tests structor's error handling.
 func TestError(t *testing.T) {
 	ev := structor.NewDefaultEvaluator(nil)